@@ -0,0 +1,186 @@
+// Package auth picks a go-git transport.AuthMethod for pushing to a remote.
+package auth
+
+import (
+	"bufio"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/bgentry/go-netrc/netrc"
+	"github.com/manifoldco/promptui"
+	"gopkg.in/src-d/go-git.v4/config"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport/http"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport/ssh"
+)
+
+// envTokens maps a remote host to the environment variable git-bump
+// checks for a token. genericTokenEnv is the fallback every host accepts.
+var envTokens = map[string]string{
+	"github.com":   "GITHUB_TOKEN",
+	"gitlab.com":   "GITLAB_TOKEN",
+	"codeberg.org": "GITEA_TOKEN",
+	"gitea.com":    "GITEA_TOKEN",
+}
+
+const genericTokenEnv = "GIT_BUMP_TOKEN"
+
+// ForRemote picks the transport.AuthMethod to use for remote, trying in
+// order: SSH agent/key for git@ or ssh:// URLs, an env-var token, netrc,
+// the git credential helper, and finally an interactive username/password
+// prompt.
+func ForRemote(remote *config.RemoteConfig) (transport.AuthMethod, error) {
+	if len(remote.URLs) == 0 {
+		return nil, fmt.Errorf("remote %q has no URL configured", remote.Name)
+	}
+	rawURL := remote.URLs[0]
+
+	if isSSH(rawURL) {
+		return sshAuth(rawURL)
+	}
+
+	host := httpHost(rawURL)
+
+	if token := tokenFromEnv(host); token != "" {
+		return &http.BasicAuth{Username: "git-bump", Password: token}, nil
+	}
+
+	if user, pass, ok := netrcAuth(host); ok {
+		return &http.BasicAuth{Username: user, Password: pass}, nil
+	}
+
+	if user, pass, ok := credentialHelperAuth(rawURL); ok {
+		return &http.BasicAuth{Username: user, Password: pass}, nil
+	}
+
+	user, pass, err := promptAuth()
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to read credentials", err)
+	}
+	return &http.BasicAuth{Username: user, Password: pass}, nil
+}
+
+func isSSH(rawURL string) bool {
+	return strings.HasPrefix(rawURL, "git@") || strings.HasPrefix(rawURL, "ssh://")
+}
+
+func sshAuth(rawURL string) (transport.AuthMethod, error) {
+	user := "git"
+	if strings.HasPrefix(rawURL, "ssh://") {
+		if u, err := url.Parse(rawURL); err == nil && u.User != nil {
+			user = u.User.Username()
+		}
+	}
+
+	if auth, err := ssh.NewSSHAgentAuth(user); err == nil {
+		return auth, nil
+	}
+
+	keyFile := os.Getenv("GIT_BUMP_SSH_KEY")
+	if keyFile == "" {
+		keyFile = defaultSSHKeyFile()
+	}
+	return ssh.NewPublicKeysFromFile(user, keyFile, os.Getenv("GIT_BUMP_SSH_KEY_PASSPHRASE"))
+}
+
+func defaultSSHKeyFile() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return home + "/.ssh/id_rsa"
+}
+
+// httpHost extracts the hostname from an http(s) remote URL.
+func httpHost(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return u.Hostname()
+}
+
+func tokenFromEnv(host string) string {
+	if name, ok := envTokens[host]; ok {
+		if token := os.Getenv(name); token != "" {
+			return token
+		}
+	}
+	return os.Getenv(genericTokenEnv)
+}
+
+// netrcAuth looks up host in $NETRC, falling back to ~/.netrc.
+func netrcAuth(host string) (string, string, bool) {
+	path := os.Getenv("NETRC")
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", "", false
+		}
+		path = filepath.Join(home, ".netrc")
+	}
+
+	n, err := netrc.ParseFile(path)
+	if err != nil {
+		return "", "", false
+	}
+
+	m := n.FindMachine(host)
+	if m == nil {
+		return "", "", false
+	}
+	return m.Login, m.Password, true
+}
+
+// credentialHelperAuth shells out to `git credential fill`, the same
+// mechanism `git push` uses, picking up whatever helper the user already
+// has configured (osxkeychain, libsecret, manager-core, ...).
+func credentialHelperAuth(rawURL string) (string, string, bool) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", "", false
+	}
+
+	cmd := exec.Command("git", "credential", "fill")
+	cmd.Stdin = strings.NewReader(fmt.Sprintf("protocol=%s\nhost=%s\npath=%s\n\n", u.Scheme, u.Host, strings.TrimPrefix(u.Path, "/")))
+	out, err := cmd.Output()
+	if err != nil {
+		return "", "", false
+	}
+
+	var user, pass string
+	scanner := bufio.NewScanner(strings.NewReader(string(out)))
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "username="):
+			user = strings.TrimPrefix(line, "username=")
+		case strings.HasPrefix(line, "password="):
+			pass = strings.TrimPrefix(line, "password=")
+		}
+	}
+	if user == "" || pass == "" {
+		return "", "", false
+	}
+	return user, pass, true
+}
+
+func promptAuth() (string, string, error) {
+	userPrompt := promptui.Prompt{Label: "Username"}
+	user, err := userPrompt.Run()
+	if err != nil {
+		return "", "", err
+	}
+
+	passPrompt := promptui.Prompt{Label: "Password", Mask: '*'}
+	pass, err := passPrompt.Run()
+	if err != nil {
+		return "", "", err
+	}
+
+	return user, pass, nil
+}
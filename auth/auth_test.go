@@ -0,0 +1,37 @@
+package auth
+
+import "testing"
+
+func TestIsSSH(t *testing.T) {
+	cases := map[string]bool{
+		"git@github.com:toshi0607/git-bump.git":       true,
+		"ssh://git@github.com/toshi0607/git-bump.git": true,
+		"https://github.com/toshi0607/git-bump.git":   false,
+		"https://gitlab.com/toshi0607/git-bump.git":   false,
+	}
+
+	for rawURL, want := range cases {
+		if got := isSSH(rawURL); got != want {
+			t.Errorf("isSSH(%q) = %v, want %v", rawURL, got, want)
+		}
+	}
+}
+
+func TestHTTPHost(t *testing.T) {
+	got := httpHost("https://github.com/toshi0607/git-bump.git")
+	if got != "github.com" {
+		t.Errorf("httpHost() = %q, want %q", got, "github.com")
+	}
+}
+
+func TestTokenFromEnv(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "from-github")
+	t.Setenv("GIT_BUMP_TOKEN", "from-generic")
+
+	if got := tokenFromEnv("github.com"); got != "from-github" {
+		t.Errorf("tokenFromEnv(github.com) = %q, want %q", got, "from-github")
+	}
+	if got := tokenFromEnv("example.com"); got != "from-generic" {
+		t.Errorf("tokenFromEnv(example.com) = %q, want %q", got, "from-generic")
+	}
+}
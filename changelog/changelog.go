@@ -0,0 +1,167 @@
+// Package changelog renders CHANGELOG.md entries and per-release notes
+// from the Conventional Commits git-bump already parses for --auto.
+package changelog
+
+import (
+	"embed"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/toshi0607/git-bump/sv"
+)
+
+//go:embed templates/*.tmpl
+var defaultTemplates embed.FS
+
+const (
+	changelogTemplateName    = "CHANGELOG.md.tmpl"
+	releaseNotesTemplateName = "RELEASE_NOTES.md.tmpl"
+)
+
+// Section is a named group of commits in a release, e.g. "Features".
+type Section struct {
+	Title   string
+	Commits []sv.Commit
+}
+
+// Release is the data made available to changelog and release-notes
+// templates.
+type Release struct {
+	Version  string
+	Date     time.Time
+	Sections []Section
+}
+
+var sectionRules = []struct {
+	title string
+	match func(sv.Commit) bool
+}{
+	{"Breaking Changes", func(c sv.Commit) bool { return c.Breaking }},
+	{"Features", func(c sv.Commit) bool { return c.Type == "feat" }},
+	{"Bug Fixes", func(c sv.Commit) bool { return c.Type == "fix" }},
+	{"Performance", func(c sv.Commit) bool { return c.Type == "perf" }},
+	{"Others", func(sv.Commit) bool { return true }},
+}
+
+// Group buckets commits into the standard sections in priority order,
+// skipping empty ones. Each commit lands in only its first matching
+// section: a breaking feat is listed under Breaking Changes, not also
+// under Features.
+func Group(commits []sv.Commit) []Section {
+	seen := make([]bool, len(commits))
+
+	var sections []Section
+	for _, rule := range sectionRules {
+		var bucket []sv.Commit
+		for i, c := range commits {
+			if seen[i] || !rule.match(c) {
+				continue
+			}
+			bucket = append(bucket, c)
+			seen[i] = true
+		}
+		if len(bucket) > 0 {
+			sections = append(sections, Section{Title: rule.title, Commits: bucket})
+		}
+	}
+
+	return sections
+}
+
+func funcs() template.FuncMap {
+	return template.FuncMap{
+		"getsection": func(sections []Section, title string) *Section {
+			for _, s := range sections {
+				if s.Title == title {
+					return &s
+				}
+			}
+			return nil
+		},
+		"timefmt": func(t time.Time, layout string) string {
+			return t.Format(layout)
+		},
+	}
+}
+
+// Render executes the changelog and release-notes templates against
+// release and returns the rendered changelog entry and release notes.
+// dir, when non-empty, is checked first for user-overridden templates
+// named CHANGELOG.md.tmpl and RELEASE_NOTES.md.tmpl; missing ones fall
+// back to the embedded defaults.
+func Render(dir string, release Release) (entry, notes string, err error) {
+	changelogTmpl, err := load(dir, changelogTemplateName)
+	if err != nil {
+		return "", "", err
+	}
+	releaseNotesTmpl, err := load(dir, releaseNotesTemplateName)
+	if err != nil {
+		return "", "", err
+	}
+
+	entry, err = execute(changelogTmpl, release)
+	if err != nil {
+		return "", "", err
+	}
+	notes, err = execute(releaseNotesTmpl, release)
+	if err != nil {
+		return "", "", err
+	}
+
+	return entry, notes, nil
+}
+
+func load(dir, name string) (*template.Template, error) {
+	if dir != "" {
+		if b, err := ioutil.ReadFile(filepath.Join(dir, name)); err == nil {
+			return template.New(name).Funcs(funcs()).Parse(string(b))
+		}
+	}
+
+	b, err := defaultTemplates.ReadFile("templates/" + name)
+	if err != nil {
+		return nil, fmt.Errorf("%w: missing default template %q", err, name)
+	}
+	return template.New(name).Funcs(funcs()).Parse(string(b))
+}
+
+func execute(tmpl *template.Template, release Release) (string, error) {
+	var buf strings.Builder
+	if err := tmpl.Execute(&buf, release); err != nil {
+		return "", fmt.Errorf("%w: failed to render template %q", err, tmpl.Name())
+	}
+	return buf.String(), nil
+}
+
+// ReleaseNotesPath is the per-release file name for a given tag, e.g.
+// release-notes-v1.2.0.md.
+func ReleaseNotesPath(version string) string {
+	return fmt.Sprintf("release-notes-%s.md", version)
+}
+
+// WriteChangelog prepends entry to changelogPath, creating it if needed.
+func WriteChangelog(changelogPath, entry string) error {
+	existing, _ := ioutil.ReadFile(changelogPath)
+
+	merged := entry
+	if len(existing) > 0 {
+		merged = entry + "\n" + string(existing)
+	}
+	if err := ioutil.WriteFile(changelogPath, []byte(merged), 0644); err != nil {
+		return fmt.Errorf("%w: failed to write %q", err, changelogPath)
+	}
+	return nil
+}
+
+// WriteReleaseNotes writes notes to version's standalone release-notes file.
+func WriteReleaseNotes(version, notes string) error {
+	path := ReleaseNotesPath(version)
+	if err := ioutil.WriteFile(path, []byte(notes), 0644); err != nil {
+		return fmt.Errorf("%w: failed to write %q", err, path)
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package changelog
+
+import (
+	"testing"
+
+	"github.com/toshi0607/git-bump/sv"
+)
+
+func TestGroup(t *testing.T) {
+	commits := []sv.Commit{
+		{Type: "feat", Description: "add widgets"},
+		{Type: "fix", Description: "fix widget crash"},
+		{Type: "feat", Breaking: true, Description: "drop legacy API"},
+		{Type: "chore", Description: "tidy up"},
+	}
+
+	sections := Group(commits)
+
+	want := map[string]int{
+		"Breaking Changes": 1,
+		"Features":         1,
+		"Bug Fixes":        1,
+		"Others":           1,
+	}
+
+	if len(sections) != len(want) {
+		t.Fatalf("Group() returned %d sections, want %d", len(sections), len(want))
+	}
+
+	for _, s := range sections {
+		if n, ok := want[s.Title]; !ok || n != len(s.Commits) {
+			t.Errorf("section %q has %d commits, want %d", s.Title, len(s.Commits), want[s.Title])
+		}
+	}
+}
+
+func TestGroupSkipsEmptySections(t *testing.T) {
+	commits := []sv.Commit{{Type: "fix", Description: "fix bug"}}
+
+	sections := Group(commits)
+	if len(sections) != 1 || sections[0].Title != "Bug Fixes" {
+		t.Errorf("Group() = %+v, want a single Bug Fixes section", sections)
+	}
+}
@@ -7,17 +7,23 @@ import (
 	"io/ioutil"
 	"os"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/Masterminds/semver/v3"
 	"github.com/Songmu/gitconfig"
 	"github.com/jessevdk/go-flags"
 	"github.com/manifoldco/promptui"
+	"github.com/toshi0607/git-bump/auth"
+	"github.com/toshi0607/git-bump/changelog"
+	"github.com/toshi0607/git-bump/sv"
+	"github.com/toshi0607/git-bump/versionfile"
 	"gopkg.in/src-d/go-git.v4"
 	"gopkg.in/src-d/go-git.v4/config"
 	"gopkg.in/src-d/go-git.v4/plumbing"
 	"gopkg.in/src-d/go-git.v4/plumbing/object"
-	"gopkg.in/src-d/go-git.v4/plumbing/transport/http"
+	"gopkg.in/src-d/go-git.v4/plumbing/storer"
 )
 
 const (
@@ -37,12 +43,35 @@ type CLI struct {
 	Stdout io.Writer
 	Stderr io.Writer
 	Repo   *git.Repository
+
+	// Commits holds the Conventional Commits parsed for this run, either
+	// by nextVersion's --auto path or by ensureCommits on demand.
+	Commits []sv.Commit
 }
 
 type Option struct {
 	Major bool `long:"major" description:"Bump up major version"`
 	Minor bool `long:"minor" description:"Bump up minor version"`
 	Patch bool `long:"patch" description:"Bump up patch version"`
+	Auto  bool `long:"auto" description:"Infer the bump from Conventional Commits since the current tag"`
+
+	Pre        string `long:"pre" description:"Prerelease label to apply, e.g. rc, beta, alpha"`
+	PreBump    bool   `long:"pre-bump" description:"Increment the current tag's prerelease counter"`
+	PrePromote bool   `long:"pre-promote" description:"Drop the prerelease from the current tag"`
+	Build      string `long:"build" description:"Build metadata to append, e.g. build.1"`
+
+	Sign   bool `long:"sign" description:"Sign the tag with the GPG key configured as user.signingkey"`
+	NoSign bool `long:"no-sign" description:"Never sign the tag, even if tag.gpgSign is set"`
+
+	Remote string `long:"remote" default:"origin" description:"Remote to push the tag to"`
+
+	Changelog    bool   `long:"changelog" description:"Prepend a CHANGELOG.md entry for this release"`
+	ReleaseNotes bool   `long:"release-notes" description:"Write a standalone release-notes-vX.Y.Z.md for this release"`
+	TemplateDir  string `long:"template-dir" description:"Directory holding CHANGELOG.md.tmpl/RELEASE_NOTES.md.tmpl overrides"`
+
+	DryRun        bool   `long:"dry-run" description:"Print what would happen without creating or pushing a tag"`
+	WriteFiles    bool   `long:"write-files" description:"Update VERSION, package.json, pyproject.toml, Cargo.toml, and .git-bump.yaml targets before tagging"`
+	CommitMessage string `long:"commit-message" default:"chore(release): %s" description:"Message template for the files/changelog commit, %s is the tag"`
 
 	Quiet bool `short:"q" long:"quiet" description:"Be quiet"`
 }
@@ -104,9 +133,185 @@ func (c *CLI) Run(args []string) error {
 		tag = Prefix + next.String()
 	}
 
+	if c.Option.DryRun {
+		return c.printDryRun(wd, current, tag)
+	}
+
+	if c.Option.WriteFiles {
+		if err := c.writeVersionFiles(wd, tag); err != nil {
+			return err
+		}
+	}
+
+	if c.Option.Changelog || c.Option.ReleaseNotes {
+		if err := c.writeChangelog(current, tag); err != nil {
+			return err
+		}
+	}
+
 	return c.PushTag(tag)
 }
 
+// printDryRun runs the same tag-selection, next-version, and changelog
+// steps as Run but never touches the working tree or origin: it only
+// reports what CreateTag/Push/writeVersionFiles would have done.
+func (c *CLI) printDryRun(wd string, current *semver.Version, tag string) error {
+	fmt.Fprintf(c.Stdout, "[dry-run] %s -> %s\n", current.Original(), tag)
+
+	if c.Option.WriteFiles {
+		cfg, err := versionfile.LoadConfig(wd)
+		if err != nil {
+			return err
+		}
+		touched, err := versionfile.Apply(wd, versionfile.Rules(tag, cfg), true)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(c.Stdout, "[dry-run] would update version files:")
+		for _, path := range touched {
+			fmt.Fprintf(c.Stdout, "  - %s\n", path)
+		}
+	}
+
+	if c.Option.Changelog || c.Option.ReleaseNotes {
+		if err := c.ensureCommits(current); err != nil {
+			return err
+		}
+
+		release := changelog.Release{Version: tag, Date: time.Now(), Sections: changelog.Group(c.Commits)}
+		entry, notes, err := changelog.Render(c.Option.TemplateDir, release)
+		if err != nil {
+			return err
+		}
+		if c.Option.Changelog {
+			fmt.Fprintln(c.Stdout, "[dry-run] CHANGELOG.md entry:")
+			fmt.Fprintln(c.Stdout, entry)
+		}
+		if c.Option.ReleaseNotes {
+			fmt.Fprintf(c.Stdout, "[dry-run] %s:\n", changelog.ReleaseNotesPath(tag))
+			fmt.Fprintln(c.Stdout, notes)
+		}
+	}
+
+	fmt.Fprintf(c.Stdout, "[dry-run] would create and push tag %q to %s\n", tag, c.Option.Remote)
+	return nil
+}
+
+// writeVersionFiles applies the built-in and .git-bump.yaml version
+// rules under wd and commits whichever files actually changed. This
+// mirrors the `npm version` workflow: VERSION/package.json/etc already
+// read the new version by the time PushTag tags the commit.
+func (c *CLI) writeVersionFiles(wd, tag string) error {
+	cfg, err := versionfile.LoadConfig(wd)
+	if err != nil {
+		return err
+	}
+
+	touched, err := versionfile.Apply(wd, versionfile.Rules(tag, cfg), false)
+	if err != nil {
+		return err
+	}
+	if len(touched) == 0 {
+		return nil
+	}
+
+	return c.commitPaths(tag, touched)
+}
+
+// writeChangelog renders whichever of the changelog/release-notes
+// templates were requested and commits the result, gating each file on
+// its own flag.
+func (c *CLI) writeChangelog(current *semver.Version, tag string) error {
+	if err := c.ensureCommits(current); err != nil {
+		return err
+	}
+
+	release := changelog.Release{
+		Version:  tag,
+		Date:     time.Now(),
+		Sections: changelog.Group(c.Commits),
+	}
+
+	entry, notes, err := changelog.Render(c.Option.TemplateDir, release)
+	if err != nil {
+		return err
+	}
+
+	var paths []string
+
+	if c.Option.Changelog {
+		if err := changelog.WriteChangelog("CHANGELOG.md", entry); err != nil {
+			return err
+		}
+		paths = append(paths, "CHANGELOG.md")
+	}
+
+	if c.Option.ReleaseNotes {
+		if err := changelog.WriteReleaseNotes(tag, notes); err != nil {
+			return err
+		}
+		paths = append(paths, changelog.ReleaseNotesPath(tag))
+	}
+
+	return c.commitPaths(tag, paths)
+}
+
+// ensureCommits makes sure c.Commits reflects the commits since current.
+// nextVersion's --auto path already populates it; this covers the case
+// where --changelog/--release-notes is combined with an explicit
+// --major/--minor/--patch instead.
+func (c *CLI) ensureCommits(current *semver.Version) error {
+	if len(c.Commits) > 0 {
+		return nil
+	}
+
+	messages, err := c.commitsSince(current)
+	if err != nil {
+		return err
+	}
+
+	_, commits, invalid := sv.Infer(messages)
+	c.Commits = commits
+	if invalid > 0 {
+		fmt.Fprintf(c.Stderr, "[WARN] skipped %d commit(s) that did not follow Conventional Commits\n", invalid)
+	}
+	return nil
+}
+
+// commitPaths stages paths and commits them using Option.CommitMessage
+// (default "chore(release): %s").
+func (c *CLI) commitPaths(tag string, paths []string) error {
+	wt, err := c.Repo.Worktree()
+	if err != nil {
+		return err
+	}
+
+	for _, path := range paths {
+		if _, err := wt.Add(path); err != nil {
+			return fmt.Errorf("%w: failed to stage %s", err, path)
+		}
+	}
+
+	user, err := gitconfig.User()
+	if err != nil {
+		return err
+	}
+	email, err := gitconfig.Email()
+	if err != nil {
+		return err
+	}
+
+	msg := c.Option.CommitMessage
+	if msg == "" {
+		msg = "chore(release): %s"
+	}
+
+	_, err = wt.Commit(fmt.Sprintf(msg, tag), &git.CommitOptions{
+		Author: &object.Signature{Name: user, Email: email, When: time.Now()},
+	})
+	return err
+}
+
 func (c Spec) String() string {
 	switch c {
 	case Major:
@@ -141,6 +346,11 @@ func (c *CLI) PushTag(tag string) error {
 		return err
 	}
 
+	key, err := c.signKey()
+	if err != nil {
+		return err
+	}
+
 	opts := &git.CreateTagOptions{
 		Tagger: &object.Signature{
 			Name:  user,
@@ -148,26 +358,44 @@ func (c *CLI) PushTag(tag string) error {
 			When:  commit.Committer.When,
 		},
 		Message: commit.Message,
-		// SignKey:
+		SignKey: key,
 	}
 
-	_, err = c.Repo.CreateTag(tag, head.Hash(), opts)
+	ref, err := c.Repo.CreateTag(tag, head.Hash(), opts)
 	if err != nil {
 		return err
 	}
 
+	if key != nil {
+		if err := c.verifyTagSignature(ref.Hash(), key); err != nil {
+			return err
+		}
+	}
+
 	fmt.Fprintf(c.Stdout, "Bump version to %q.\n", tag)
 
+	remoteName := c.Option.Remote
+	if remoteName == "" {
+		remoteName = "origin"
+	}
+
+	remote, err := c.Repo.Remote(remoteName)
+	if err != nil {
+		return fmt.Errorf("%w: remote %q not found", err, remoteName)
+	}
+
+	pushAuth, err := auth.ForRemote(remote.Config())
+	if err != nil {
+		return err
+	}
+
 	rs := config.RefSpec(fmt.Sprintf("refs/tags/%s:refs/tags/%s", tag, tag))
 	// rs := config.RefSpec("refs/tags/*:refs/tags/*")
 
-	defer fmt.Fprintf(c.Stdout, "Pushed to origin.\n")
+	defer fmt.Fprintf(c.Stdout, "Pushed to %s.\n", remoteName)
 	return c.Repo.Push(&git.PushOptions{
-		Auth: &http.BasicAuth{
-			Username: user,
-			Password: os.Getenv("GITHUB_TOKEN"),
-		},
-		RemoteName: "origin",
+		Auth:       pushAuth,
+		RemoteName: remoteName,
 		RefSpecs:   []config.RefSpec{rs},
 		Progress:   c.Stdout,
 	})
@@ -231,6 +459,36 @@ func (c *CLI) prompt(label string, items []Spec) (Spec, error) {
 
 func (c *CLI) nextVersion(current *semver.Version) (semver.Version, error) {
 	var next semver.Version
+	var err error
+
+	switch {
+	case c.Option.PrePromote:
+		next, err = c.promote(current)
+	case c.Option.PreBump:
+		next, err = c.bumpPrerelease(current)
+	default:
+		next, err = c.nextReleaseVersion(current)
+	}
+	if err != nil {
+		return next, err
+	}
+
+	if c.Option.Build != "" {
+		withMeta, err := next.SetMetadata(c.Option.Build)
+		if err != nil {
+			return next, fmt.Errorf("%w: invalid build metadata %q", err, c.Option.Build)
+		}
+		next = withMeta
+	}
+
+	return next, nil
+}
+
+// nextReleaseVersion runs the --major/--minor/--patch/--auto/prompt flow
+// used to pick which component to bump, then either applies it directly
+// or, with --pre, folds it into a prerelease of that component.
+func (c *CLI) nextReleaseVersion(current *semver.Version) (semver.Version, error) {
+	var next semver.Version
 
 	specs := []Spec{}
 	if c.Option.Major {
@@ -243,6 +501,17 @@ func (c *CLI) nextVersion(current *semver.Version) (semver.Version, error) {
 		specs = append(specs, Patch)
 	}
 
+	if c.Option.Auto && len(specs) == 0 {
+		spec, ok, err := c.autoSpec(current)
+		if err != nil {
+			return next, err
+		}
+		if ok {
+			return c.applyBump(current, spec)
+		}
+		fmt.Fprintln(c.Stdout, "No version-bumping Conventional Commits found since the current tag, falling back to prompt.")
+	}
+
 	label := fmt.Sprintf("Current tag is %q. Next is?", current.Original())
 
 	var spec Spec
@@ -265,16 +534,171 @@ func (c *CLI) nextVersion(current *semver.Version) (semver.Version, error) {
 		}
 	}
 
+	return c.applyBump(current, spec)
+}
+
+// applyBump increments the component picked by spec and, with --pre,
+// folds the result into a prerelease instead of a final release.
+func (c *CLI) applyBump(current *semver.Version, spec Spec) (semver.Version, error) {
+	if c.Option.Pre != "" {
+		return c.applyPrerelease(current, spec, c.Option.Pre)
+	}
+	return c.applySpec(current, spec)
+}
+
+func (c *CLI) applySpec(current *semver.Version, spec Spec) (semver.Version, error) {
 	switch spec {
 	case Major:
-		next = current.IncMajor()
+		return current.IncMajor(), nil
 	case Minor:
-		next = current.IncMinor()
+		return current.IncMinor(), nil
 	case Patch:
-		next = current.IncPatch()
+		return current.IncPatch(), nil
 	default:
-		return next, errors.New("invalid semver")
+		return semver.Version{}, errors.New("invalid semver")
 	}
+}
+
+// applyPrerelease folds spec's bump into a prerelease tagged label. If
+// current is itself an unreleased prerelease of the same core version
+// under the same label, its counter is incremented (v1.2.0-rc.1 ->
+// v1.2.0-rc.2) instead of restarting the series.
+func (c *CLI) applyPrerelease(current *semver.Version, spec Spec, label string) (semver.Version, error) {
+	bumped, err := c.applySpec(current, spec)
+	if err != nil {
+		return semver.Version{}, err
+	}
+
+	if current.Prerelease() != "" && sameCore(current, &bumped) {
+		if existingLabel, _ := splitPrerelease(current.Prerelease()); existingLabel == label {
+			return bumpPrereleaseCounter(current, label)
+		}
+	}
+
+	withPre, err := bumped.SetPrerelease(fmt.Sprintf("%s.1", label))
+	if err != nil {
+		return semver.Version{}, fmt.Errorf("%w: invalid prerelease label %q", err, label)
+	}
+	return withPre, nil
+}
 
+// promote drops the prerelease from current, e.g. v1.2.0-rc.3 -> v1.2.0.
+func (c *CLI) promote(current *semver.Version) (semver.Version, error) {
+	if current.Prerelease() == "" {
+		return semver.Version{}, fmt.Errorf("current tag %q has no prerelease to promote", current.Original())
+	}
+	next, err := current.SetPrerelease("")
+	if err != nil {
+		return semver.Version{}, fmt.Errorf("%w: failed to promote %q", err, current.Original())
+	}
+	return next, nil
+}
+
+// bumpPrerelease increments current's prerelease counter in place, e.g.
+// v1.2.0-rc.1 -> v1.2.0-rc.2. --pre overrides the label if set, which
+// lets a run switch series (e.g. beta -> rc) while bumping.
+func (c *CLI) bumpPrerelease(current *semver.Version) (semver.Version, error) {
+	if current.Prerelease() == "" {
+		return semver.Version{}, fmt.Errorf("current tag %q has no prerelease to bump", current.Original())
+	}
+
+	label := c.Option.Pre
+	if label == "" {
+		label, _ = splitPrerelease(current.Prerelease())
+	}
+	return bumpPrereleaseCounter(current, label)
+}
+
+func bumpPrereleaseCounter(current *semver.Version, label string) (semver.Version, error) {
+	existingLabel, counter := splitPrerelease(current.Prerelease())
+	if existingLabel != label {
+		counter = 0
+	}
+
+	next, err := current.SetPrerelease(fmt.Sprintf("%s.%d", label, counter+1))
+	if err != nil {
+		return semver.Version{}, fmt.Errorf("%w: failed to bump prerelease %q", err, current.Prerelease())
+	}
 	return next, nil
 }
+
+// splitPrerelease splits a "label.N" prerelease string into its label
+// and numeric counter, defaulting the counter to 0 when absent or
+// non-numeric.
+func splitPrerelease(prerelease string) (string, int) {
+	parts := strings.SplitN(prerelease, ".", 2)
+	if len(parts) != 2 {
+		return parts[0], 0
+	}
+	n, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return parts[0], 0
+	}
+	return parts[0], n
+}
+
+func sameCore(a, b *semver.Version) bool {
+	return a.Major() == b.Major() && a.Minor() == b.Minor() && a.Patch() == b.Patch()
+}
+
+// autoSpec walks the commits between current and HEAD and infers a bump
+// from their Conventional Commits headers, leaving the parsed commits on
+// c.Commits either way. ok is false when none of them implies a bump
+// (no Conventional Commits, or only types such as chore or docs), meaning
+// the caller should fall back to the interactive prompt.
+func (c *CLI) autoSpec(current *semver.Version) (Spec, bool, error) {
+	messages, err := c.commitsSince(current)
+	if err != nil {
+		return 0, false, err
+	}
+
+	bump, commits, invalid := sv.Infer(messages)
+	c.Commits = commits
+	if invalid > 0 {
+		fmt.Fprintf(c.Stderr, "[WARN] skipped %d commit(s) that did not follow Conventional Commits\n", invalid)
+	}
+
+	switch bump {
+	case sv.Major:
+		return Major, true, nil
+	case sv.Minor:
+		return Minor, true, nil
+	case sv.Patch:
+		return Patch, true, nil
+	default:
+		return 0, false, nil
+	}
+}
+
+// commitsSince returns the full messages of every commit reachable from
+// HEAD down to, but not including, the commit tagged as current.
+func (c *CLI) commitsSince(current *semver.Version) ([]string, error) {
+	hash, err := c.Repo.ResolveRevision(plumbing.Revision(current.Original()))
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to resolve tag %q", err, current.Original())
+	}
+
+	head, err := c.Repo.Head()
+	if err != nil {
+		return nil, err
+	}
+
+	cIter, err := c.Repo.Log(&git.LogOptions{From: head.Hash()})
+	if err != nil {
+		return nil, err
+	}
+
+	var messages []string
+	err = cIter.ForEach(func(commit *object.Commit) error {
+		if commit.Hash == *hash {
+			return storer.ErrStop
+		}
+		messages = append(messages, commit.Message)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return messages, nil
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+func TestSplitPrerelease(t *testing.T) {
+	cases := []struct {
+		in        string
+		wantLabel string
+		wantN     int
+	}{
+		{"rc.1", "rc", 1},
+		{"rc.9", "rc", 9},
+		{"rc", "rc", 0},
+		{"beta.abc", "beta", 0},
+	}
+
+	for _, c := range cases {
+		label, n := splitPrerelease(c.in)
+		if label != c.wantLabel || n != c.wantN {
+			t.Errorf("splitPrerelease(%q) = (%q, %d), want (%q, %d)", c.in, label, n, c.wantLabel, c.wantN)
+		}
+	}
+}
+
+func TestBumpPrereleaseCounter(t *testing.T) {
+	current := semver.MustParse("v1.2.0-rc.1")
+	next, err := bumpPrereleaseCounter(current, "rc")
+	if err != nil {
+		t.Fatalf("bumpPrereleaseCounter() error = %v", err)
+	}
+	if got, want := next.Prerelease(), "rc.2"; got != want {
+		t.Errorf("bumpPrereleaseCounter() prerelease = %q, want %q", got, want)
+	}
+
+	// Switching labels resets the counter instead of continuing it.
+	next, err = bumpPrereleaseCounter(current, "beta")
+	if err != nil {
+		t.Fatalf("bumpPrereleaseCounter() error = %v", err)
+	}
+	if got, want := next.Prerelease(), "beta.1"; got != want {
+		t.Errorf("bumpPrereleaseCounter() prerelease = %q, want %q", got, want)
+	}
+}
+
+func TestApplyPrerelease(t *testing.T) {
+	c := &CLI{}
+
+	// A stable current tag starts a new prerelease series for the
+	// requested component.
+	current := semver.MustParse("v1.2.0")
+	next, err := c.applyPrerelease(current, Minor, "rc")
+	if err != nil {
+		t.Fatalf("applyPrerelease() error = %v", err)
+	}
+	if got, want := next.String(), "1.3.0-rc.1"; got != want {
+		t.Errorf("applyPrerelease(v1.2.0, Minor, rc) = %q, want %q", got, want)
+	}
+
+	// Requesting a bigger component than the one the current prerelease
+	// was started for must escalate, not just bump the existing counter.
+	current = semver.MustParse("v1.2.0-rc.1")
+	next, err = c.applyPrerelease(current, Minor, "rc")
+	if err != nil {
+		t.Fatalf("applyPrerelease() error = %v", err)
+	}
+	if got, want := next.String(), "1.3.0-rc.1"; got != want {
+		t.Errorf("applyPrerelease(v1.2.0-rc.1, Minor, rc) = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/Songmu/gitconfig"
+	"github.com/manifoldco/promptui"
+	"golang.org/x/crypto/openpgp"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+)
+
+// signKey resolves the GPG signing key to use for the tag, following the
+// same precedence git itself uses: an explicit --sign/--no-sign flag
+// wins, otherwise tag.gpgSign from gitconfig decides. It returns a nil
+// entity (and no error) when signing isn't requested.
+func (c *CLI) signKey() (*openpgp.Entity, error) {
+	if c.Option.NoSign {
+		return nil, nil
+	}
+
+	configured, _ := gitconfig.Bool("tag.gpgSign")
+	if !c.Option.Sign && !configured {
+		return nil, nil
+	}
+
+	keyID, err := gitconfig.Get("user.signingkey")
+	if err != nil || keyID == "" {
+		return nil, fmt.Errorf("tag signing requested but user.signingkey is not set")
+	}
+
+	program, err := gitconfig.Get("gpg.program")
+	if err != nil || program == "" {
+		program = "gpg"
+	}
+
+	// Resolve the passphrase before export: gpg-agent may need to unlock
+	// the key to export it at all, and without --pinentry-mode loopback
+	// that unlock silently fails wherever there's no TTY/pinentry, i.e.
+	// in CI.
+	pass := os.Getenv("GPG_PASSPHRASE")
+
+	armored, err := exportSecretKey(program, keyID, pass)
+	if err != nil {
+		return nil, err
+	}
+
+	keyring, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(armored))
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to parse signing key %q", err, keyID)
+	}
+	if len(keyring) == 0 {
+		return nil, fmt.Errorf("no signing key found for %q", keyID)
+	}
+	entity := keyring[0]
+
+	if err := decryptKey(entity); err != nil {
+		return nil, err
+	}
+
+	return entity, nil
+}
+
+// exportSecretKey exports keyID in armored form via program. When pass is
+// set (GPG_PASSPHRASE), it's forwarded over --passphrase-fd, letting
+// gpg-agent unlock the key without a pinentry prompt — the difference
+// between this working and failing in a CI runner with no TTY.
+func exportSecretKey(program, keyID, pass string) ([]byte, error) {
+	args := []string{"--armor", "--export-secret-keys"}
+	if pass != "" {
+		args = append(args, "--batch", "--pinentry-mode", "loopback", "--passphrase-fd", "0")
+	}
+	args = append(args, keyID)
+
+	cmd := exec.Command(program, args...)
+	if pass != "" {
+		cmd.Stdin = strings.NewReader(pass)
+	}
+
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to export signing key %q via %s", err, keyID, program)
+	}
+	return out, nil
+}
+
+func decryptKey(entity *openpgp.Entity) error {
+	if entity.PrivateKey == nil || !entity.PrivateKey.Encrypted {
+		return nil
+	}
+
+	pass, err := passphrase()
+	if err != nil {
+		return fmt.Errorf("%w: failed to read GPG passphrase", err)
+	}
+
+	if err := entity.PrivateKey.Decrypt([]byte(pass)); err != nil {
+		return fmt.Errorf("%w: failed to decrypt signing key", err)
+	}
+	for _, sub := range entity.Subkeys {
+		if sub.PrivateKey != nil && sub.PrivateKey.Encrypted {
+			if err := sub.PrivateKey.Decrypt([]byte(pass)); err != nil {
+				return fmt.Errorf("%w: failed to decrypt signing subkey", err)
+			}
+		}
+	}
+	return nil
+}
+
+func passphrase() (string, error) {
+	if pass := os.Getenv("GPG_PASSPHRASE"); pass != "" {
+		return pass, nil
+	}
+
+	prompt := promptui.Prompt{
+		Label: "GPG key passphrase",
+		Mask:  '*',
+	}
+	return prompt.Run()
+}
+
+// verifyTagSignature re-checks the signature on the just-created tag
+// against entity. A bad key fails here instead of at `git tag -v` or on
+// origin's receive side.
+func (c *CLI) verifyTagSignature(hash plumbing.Hash, entity *openpgp.Entity) error {
+	tagObj, err := c.Repo.TagObject(hash)
+	if err != nil {
+		return err
+	}
+	if tagObj.PGPSignature == "" {
+		return fmt.Errorf("tag %q was created without a PGP signature", tagObj.Name)
+	}
+
+	encoded := &plumbing.MemoryObject{}
+	if err := tagObj.EncodeWithoutSignature(encoded); err != nil {
+		return fmt.Errorf("%w: failed to encode tag for signature verification", err)
+	}
+	r, err := encoded.Reader()
+	if err != nil {
+		return err
+	}
+
+	keyring := openpgp.EntityList{entity}
+	if _, err := openpgp.CheckArmoredDetachedSignature(keyring, r, strings.NewReader(tagObj.PGPSignature)); err != nil {
+		return fmt.Errorf("%w: tag signature verification failed", err)
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"testing"
+
+	"golang.org/x/crypto/openpgp"
+)
+
+func TestDecryptKeyUnlocksPassphraseProtectedKey(t *testing.T) {
+	entity, err := openpgp.NewEntity("Test User", "", "test@example.com", nil)
+	if err != nil {
+		t.Fatalf("NewEntity() error = %v", err)
+	}
+
+	pass := "hunter2"
+	if err := entity.PrivateKey.Encrypt([]byte(pass)); err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+	if !entity.PrivateKey.Encrypted {
+		t.Fatal("expected private key to be encrypted before decryptKey")
+	}
+
+	t.Setenv("GPG_PASSPHRASE", pass)
+	if err := decryptKey(entity); err != nil {
+		t.Fatalf("decryptKey() error = %v", err)
+	}
+	if entity.PrivateKey.Encrypted {
+		t.Error("decryptKey() left private key encrypted")
+	}
+}
+
+func TestDecryptKeyNoopForUnencryptedKey(t *testing.T) {
+	entity, err := openpgp.NewEntity("Test User", "", "test@example.com", nil)
+	if err != nil {
+		t.Fatalf("NewEntity() error = %v", err)
+	}
+
+	if err := decryptKey(entity); err != nil {
+		t.Fatalf("decryptKey() error = %v", err)
+	}
+}
+
+func TestPassphraseFromEnv(t *testing.T) {
+	t.Setenv("GPG_PASSPHRASE", "sekrit")
+
+	got, err := passphrase()
+	if err != nil {
+		t.Fatalf("passphrase() error = %v", err)
+	}
+	if got != "sekrit" {
+		t.Errorf("passphrase() = %q, want %q", got, "sekrit")
+	}
+}
@@ -0,0 +1,127 @@
+// Package sv implements the parts of the Conventional Commits
+// specification (https://www.conventionalcommits.org) that git-bump needs
+// to infer a semver bump from commit history.
+package sv
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Bump is the severity of a semver change implied by a set of commits.
+type Bump int
+
+const (
+	None Bump = iota
+	Patch
+	Minor
+	Major
+)
+
+func (b Bump) String() string {
+	switch b {
+	case Major:
+		return "major"
+	case Minor:
+		return "minor"
+	case Patch:
+		return "patch"
+	default:
+		return "none"
+	}
+}
+
+// Commit is a single commit whose subject matched the Conventional
+// Commits header grammar: type(scope)!: description
+type Commit struct {
+	Subject     string
+	Type        string
+	Scope       string
+	Breaking    bool
+	Description string
+}
+
+var (
+	headerRe         = regexp.MustCompile(`^([a-zA-Z]+)(\(([^)]+)\))?(!)?:\s*(.+)$`)
+	breakingFooterRe = regexp.MustCompile(`(?i)^BREAKING[ -]CHANGE:`)
+)
+
+// patchTypes are the Conventional Commits types that trigger a patch
+// bump. Everything else (docs, style, test, chore, ci, build, ...) is
+// parsed but does not move the version on its own.
+var patchTypes = map[string]bool{
+	"fix":      true,
+	"perf":     true,
+	"refactor": true,
+}
+
+// Infer scans commit messages (full "subject\n\nbody" text, as returned
+// by object.Commit.Message) for Conventional Commits and returns the
+// highest severity bump across all of them, the commits that parsed
+// successfully, and a count of the ones that didn't.
+func Infer(messages []string) (Bump, []Commit, int) {
+	var bump Bump
+	var commits []Commit
+	var invalid int
+
+	for _, msg := range messages {
+		commit, ok := parse(msg)
+		if !ok {
+			invalid++
+			continue
+		}
+
+		commits = append(commits, commit)
+		if b := severity(commit); b > bump {
+			bump = b
+		}
+	}
+
+	return bump, commits, invalid
+}
+
+func parse(msg string) (Commit, bool) {
+	lines := strings.SplitN(msg, "\n", 2)
+	subject := strings.TrimSpace(lines[0])
+
+	m := headerRe.FindStringSubmatch(subject)
+	if m == nil {
+		return Commit{}, false
+	}
+
+	commit := Commit{
+		Subject:     subject,
+		Type:        strings.ToLower(m[1]),
+		Scope:       m[3],
+		Breaking:    m[4] == "!",
+		Description: m[5],
+	}
+
+	if len(lines) > 1 && hasBreakingFooter(lines[1]) {
+		commit.Breaking = true
+	}
+
+	return commit, true
+}
+
+func hasBreakingFooter(body string) bool {
+	for _, line := range strings.Split(body, "\n") {
+		if breakingFooterRe.MatchString(strings.TrimSpace(line)) {
+			return true
+		}
+	}
+	return false
+}
+
+func severity(c Commit) Bump {
+	switch {
+	case c.Breaking:
+		return Major
+	case c.Type == "feat":
+		return Minor
+	case patchTypes[c.Type]:
+		return Patch
+	default:
+		return None
+	}
+}
@@ -0,0 +1,85 @@
+package sv
+
+import "testing"
+
+func TestInfer(t *testing.T) {
+	cases := []struct {
+		name     string
+		messages []string
+		wantBump Bump
+		wantN    int
+		wantInv  int
+	}{
+		{
+			name:     "feat is minor",
+			messages: []string{"feat: add widgets"},
+			wantBump: Minor,
+			wantN:    1,
+		},
+		{
+			name:     "fix is patch",
+			messages: []string{"fix: crash on empty input"},
+			wantBump: Patch,
+			wantN:    1,
+		},
+		{
+			name:     "bang after type is major",
+			messages: []string{"feat!: drop legacy API"},
+			wantBump: Major,
+			wantN:    1,
+		},
+		{
+			name:     "breaking change footer is major",
+			messages: []string{"fix: tweak retry\n\nBREAKING CHANGE: removes the old retry() signature"},
+			wantBump: Major,
+			wantN:    1,
+		},
+		{
+			name:     "highest severity across commits wins",
+			messages: []string{"fix: a", "feat: b", "chore: c"},
+			wantBump: Minor,
+			wantN:    3,
+		},
+		{
+			name:     "non-conventional commit is counted as invalid",
+			messages: []string{"wip"},
+			wantBump: None,
+			wantInv:  1,
+		},
+		{
+			name:     "scope is parsed but doesn't affect severity",
+			messages: []string{"fix(parser): handle trailing commas"},
+			wantBump: Patch,
+			wantN:    1,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			bump, commits, invalid := Infer(c.messages)
+			if bump != c.wantBump {
+				t.Errorf("bump = %v, want %v", bump, c.wantBump)
+			}
+			if len(commits) != c.wantN {
+				t.Errorf("len(commits) = %d, want %d", len(commits), c.wantN)
+			}
+			if invalid != c.wantInv {
+				t.Errorf("invalid = %d, want %d", invalid, c.wantInv)
+			}
+		})
+	}
+}
+
+func TestBumpString(t *testing.T) {
+	cases := map[Bump]string{
+		Major: "major",
+		Minor: "minor",
+		Patch: "patch",
+		None:  "none",
+	}
+	for bump, want := range cases {
+		if got := bump.String(); got != want {
+			t.Errorf("Bump(%d).String() = %q, want %q", bump, got, want)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+// Package versionfile keeps version-carrying files (VERSION,
+// package.json, pyproject.toml, Cargo.toml, and user-declared ones) in
+// sync with the tag git-bump is about to create, the same way `npm
+// version` updates package.json before tagging.
+package versionfile
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Rule rewrites the first match of Pattern in Path to Replace. %s in
+// Replace (from config) is substituted with the release version before
+// the rule runs.
+type Rule struct {
+	Path    string `yaml:"path"`
+	Pattern string `yaml:"pattern"`
+	Replace string `yaml:"replace"`
+}
+
+// Config is the shape of .git-bump.yaml: extra files/patterns to keep in
+// sync, alongside the built-in ones.
+type Config struct {
+	Files []Rule `yaml:"files"`
+}
+
+const configName = ".git-bump.yaml"
+
+// LoadConfig reads .git-bump.yaml from dir, returning a zero Config (not
+// an error) when the file doesn't exist.
+func LoadConfig(dir string) (*Config, error) {
+	b, err := ioutil.ReadFile(filepath.Join(dir, configName))
+	if os.IsNotExist(err) {
+		return &Config{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal(b, &cfg); err != nil {
+		return nil, fmt.Errorf("%w: failed to parse %s", err, configName)
+	}
+	return &cfg, nil
+}
+
+// builtinRules covers the version files most projects keep alongside the
+// tag. They're only applied when the file exists.
+func builtinRules(version string) []Rule {
+	return []Rule{
+		{Path: "VERSION", Pattern: `(?s).*`, Replace: version + "\n"},
+		{Path: "package.json", Pattern: `"version"\s*:\s*"[^"]*"`, Replace: fmt.Sprintf(`"version": "%s"`, version)},
+		{Path: "pyproject.toml", Pattern: `(?m)^version\s*=\s*"[^"]*"`, Replace: fmt.Sprintf(`version = "%s"`, version)},
+		{Path: "Cargo.toml", Pattern: `(?m)^version\s*=\s*"[^"]*"`, Replace: fmt.Sprintf(`version = "%s"`, version)},
+	}
+}
+
+// Rules returns the full set of rules - built-in plus cfg's - that Apply
+// would consider, with %s already substituted for version.
+func Rules(version string, cfg *Config) []Rule {
+	rules := builtinRules(version)
+	for _, r := range cfg.Files {
+		rules = append(rules, Rule{
+			Path:    r.Path,
+			Pattern: r.Pattern,
+			Replace: strings.ReplaceAll(r.Replace, "%s", version),
+		})
+	}
+	return rules
+}
+
+// Apply runs rules against files under dir, skipping any whose Path
+// doesn't exist. When dryRun is true, no files are modified; Apply only
+// reports which existing paths would have been. It returns the paths
+// (relative to dir) that were or would have been changed.
+func Apply(dir string, rules []Rule, dryRun bool) ([]string, error) {
+	var touched []string
+
+	for _, r := range rules {
+		path := filepath.Join(dir, r.Path)
+		b, err := ioutil.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return touched, err
+		}
+
+		re, err := regexp.Compile(r.Pattern)
+		if err != nil {
+			return touched, fmt.Errorf("%w: invalid pattern for %s", err, r.Path)
+		}
+
+		loc := re.FindSubmatchIndex(b)
+		if loc == nil {
+			continue
+		}
+		updated := append([]byte{}, b[:loc[0]]...)
+		updated = re.Expand(updated, []byte(r.Replace), b, loc)
+		updated = append(updated, b[loc[1]:]...)
+		if bytes.Equal(updated, b) {
+			continue
+		}
+
+		if dryRun {
+			touched = append(touched, r.Path)
+			continue
+		}
+
+		if err := ioutil.WriteFile(path, updated, 0644); err != nil {
+			return touched, fmt.Errorf("%w: failed to write %s", err, path)
+		}
+		touched = append(touched, r.Path)
+	}
+
+	return touched, nil
+}
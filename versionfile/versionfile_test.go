@@ -0,0 +1,76 @@
+package versionfile
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestApplyBuiltinRules(t *testing.T) {
+	dir := t.TempDir()
+
+	files := map[string]string{
+		"VERSION":      "0.1.0\n",
+		"package.json": "{\n  \"name\": \"x\",\n  \"version\": \"0.1.0\"\n}\n",
+		"Cargo.toml":   "[package]\nname = \"x\"\nversion = \"0.1.0\"\n",
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("failed to seed %s: %v", name, err)
+		}
+	}
+
+	touched, err := Apply(dir, Rules("1.2.0", &Config{}), false)
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+
+	wantTouched := map[string]bool{"VERSION": true, "package.json": true, "Cargo.toml": true}
+	if len(touched) != len(wantTouched) {
+		t.Fatalf("Apply() touched %v, want %d files", touched, len(wantTouched))
+	}
+
+	version, err := ioutil.ReadFile(filepath.Join(dir, "VERSION"))
+	if err != nil || string(version) != "1.2.0\n" {
+		t.Errorf("VERSION = %q, err %v, want %q", version, err, "1.2.0\n")
+	}
+
+	pkg, err := ioutil.ReadFile(filepath.Join(dir, "package.json"))
+	if err != nil || !strings.Contains(string(pkg), `"version": "1.2.0"`) {
+		t.Errorf("package.json = %q, want it to contain the new version", pkg)
+	}
+}
+
+func TestApplySkipsMissingFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	touched, err := Apply(dir, Rules("1.2.0", &Config{}), false)
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if len(touched) != 0 {
+		t.Errorf("Apply() touched %v in an empty dir, want none", touched)
+	}
+}
+
+func TestApplyDryRunDoesNotWrite(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "VERSION")
+	if err := ioutil.WriteFile(path, []byte("0.1.0\n"), 0644); err != nil {
+		t.Fatalf("failed to seed VERSION: %v", err)
+	}
+
+	touched, err := Apply(dir, Rules("1.2.0", &Config{}), true)
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if len(touched) != 1 || touched[0] != "VERSION" {
+		t.Errorf("Apply(dryRun) touched = %v, want [VERSION]", touched)
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil || string(b) != "0.1.0\n" {
+		t.Errorf("VERSION was modified during a dry run: %q", b)
+	}
+}